@@ -0,0 +1,91 @@
+package syslog
+
+import (
+	"log"
+	"time"
+)
+
+// BatchHandler buffers messages and flushes them as a single slice to fn
+// once maxBatchSize messages have accumulated or maxLinger has elapsed
+// since the first message of the current batch, whichever comes first.
+// This is the shape needed for bulk-oriented sinks such as Elasticsearch's
+// _bulk API, one S3 object per N events, or a Kafka producer with linger.
+type BatchHandler struct {
+	maxBatchSize int
+	maxLinger    time.Duration
+	fn           func([]SyslogMessage) error
+
+	intake *BaseHandler
+}
+
+// NewBatchHandler creates a BatchHandler that flushes batches of up to
+// maxBatchSize messages to fn, or sooner if maxLinger elapses since the
+// first message of the current batch.
+func NewBatchHandler(maxBatchSize int, maxLinger time.Duration, fn func([]SyslogMessage) error) *BatchHandler {
+	bh := &BatchHandler{
+		maxBatchSize: maxBatchSize,
+		maxLinger:    maxLinger,
+		fn:           fn,
+		intake:       NewBaseHandler(maxBatchSize*2, nil, false),
+	}
+	go bh.loop()
+	return bh
+}
+
+// Handle queues m on the handler's internal intake queue. If m is nil it
+// flushes the partial batch, waits for that flush to complete, and shuts
+// the handler down before returning.
+func (bh *BatchHandler) Handle(m SyslogMessage) SyslogMessage {
+	return bh.intake.Handle(m)
+}
+
+// loop is the single flusher goroutine. It owns the current batch, so no
+// locking is needed between accumulation and flushing.
+func (bh *BatchHandler) loop() {
+	var batch []SyslogMessage
+
+	timer := time.NewTimer(bh.maxLinger)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	armed := false
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		toFlush := batch
+		batch = nil
+		if err := bh.fn(toFlush); err != nil {
+			log.Printf("syslog: batch flush of %d message(s) failed: %v", len(toFlush), err)
+		}
+	}
+
+	queue := bh.intake.Queue()
+	for {
+		select {
+		case m, ok := <-queue:
+			if !ok {
+				flush()
+				bh.intake.End()
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(bh.maxLinger)
+				armed = true
+			}
+			batch = append(batch, m)
+			if len(batch) >= bh.maxBatchSize {
+				if armed && !timer.Stop() {
+					<-timer.C
+				}
+				armed = false
+				flush()
+			}
+
+		case <-timer.C:
+			armed = false
+			flush()
+		}
+	}
+}
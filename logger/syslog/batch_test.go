@@ -0,0 +1,66 @@
+package syslog
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchHandlerFlushesPartialBatchOnShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var flushed [][]SyslogMessage
+
+	bh := NewBatchHandler(10, time.Hour, func(batch []SyslogMessage) error {
+		mu.Lock()
+		flushed = append(flushed, batch)
+		mu.Unlock()
+		return nil
+	})
+
+	bh.Handle(SyslogMessage{"i": 1})
+	bh.Handle(SyslogMessage{"i": 2})
+	bh.Handle(nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(flushed) != 1 || len(flushed[0]) != 2 {
+		t.Fatalf("expected one flush of the 2-message partial batch on shutdown, got %v", flushed)
+	}
+}
+
+func TestBatchHandlerFlushesOnMaxBatchSize(t *testing.T) {
+	var mu sync.Mutex
+	var flushed [][]SyslogMessage
+
+	bh := NewBatchHandler(2, time.Hour, func(batch []SyslogMessage) error {
+		mu.Lock()
+		flushed = append(flushed, batch)
+		mu.Unlock()
+		return nil
+	})
+
+	bh.Handle(SyslogMessage{"i": 1})
+	bh.Handle(SyslogMessage{"i": 2})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(flushed)
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for size-triggered flush")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	bh.Handle(nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(flushed) != 1 {
+		t.Fatalf("expected exactly one flush triggered by MaxBatchSize, got %d", len(flushed))
+	}
+}
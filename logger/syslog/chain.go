@@ -0,0 +1,23 @@
+package syslog
+
+// HandlerFunc adapts a plain function to the Handler interface, the same
+// way http.HandlerFunc does for http.Handler.
+type HandlerFunc func(SyslogMessage) SyslogMessage
+
+// Handle calls f(m).
+func (f HandlerFunc) Handle(m SyslogMessage) SyslogMessage {
+	return f(m)
+}
+
+// Middleware wraps a Handler to add cross-cutting behavior, such as the
+// ones in the syslog/middleware subpackage.
+type Middleware func(Handler) Handler
+
+// Chain applies mw to h in order, so that the first Middleware is
+// outermost: Chain(h, A, B) behaves like A(B(h)).
+func Chain(h Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+	return h
+}
@@ -0,0 +1,47 @@
+package syslog
+
+// Facility is the syslog facility code of a message, as defined by RFC 5424.
+type Facility int
+
+// Facility codes, in the order assigned by RFC 5424.
+const (
+	LOG_KERN Facility = iota
+	LOG_USER
+	LOG_MAIL
+	LOG_DAEMON
+	LOG_AUTH
+	LOG_SYSLOG
+	LOG_LPR
+	LOG_NEWS
+	LOG_UUCP
+	LOG_CRON
+	LOG_AUTHPRIV
+	LOG_FTP
+	_
+	_
+	_
+	_
+	LOG_LOCAL0
+	LOG_LOCAL1
+	LOG_LOCAL2
+	LOG_LOCAL3
+	LOG_LOCAL4
+	LOG_LOCAL5
+	LOG_LOCAL6
+	LOG_LOCAL7
+)
+
+// Severity is the syslog severity level of a message, as defined by RFC 5424.
+type Severity int
+
+// Severity levels, from most to least severe.
+const (
+	LOG_EMERG Severity = iota
+	LOG_ALERT
+	LOG_CRIT
+	LOG_ERR
+	LOG_WARNING
+	LOG_NOTICE
+	LOG_INFO
+	LOG_DEBUG
+)
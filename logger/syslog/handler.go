@@ -1,5 +1,11 @@
 package syslog
 
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // Handler handles syslog messages
 type Handler interface {
 	// Handle should return Message (maybe modified) for future processing by
@@ -8,32 +14,152 @@ type Handler interface {
 	Handle(SyslogMessage) SyslogMessage
 }
 
+// OverflowPolicy decides what happens to a message that arrives while a
+// BaseHandler's internal queue is full. It returns true if m ended up
+// enqueued (or was otherwise fully handled), or false if m was dropped.
+type OverflowPolicy func(h *BaseHandler, m SyslogMessage) bool
+
+// DropNewest discards the incoming message, leaving the queue unchanged.
+// This is the policy used by NewBaseHandler and matches BaseHandler's
+// historical behavior.
+func DropNewest() OverflowPolicy {
+	return func(h *BaseHandler, m SyslogMessage) bool {
+		select {
+		case h.queue <- m:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// DropOldest evicts the single oldest queued message, if any, to make room
+// for the incoming one.
+func DropOldest() OverflowPolicy {
+	return func(h *BaseHandler, m SyslogMessage) bool {
+		for {
+			select {
+			case h.queue <- m:
+				return true
+			default:
+				select {
+				case <-h.queue:
+					h.stats.addDropped()
+				default:
+				}
+			}
+		}
+	}
+}
+
+// BlockWithTimeout blocks enqueueing the incoming message for up to d
+// before giving up and dropping it.
+func BlockWithTimeout(d time.Duration) OverflowPolicy {
+	return func(h *BaseHandler, m SyslogMessage) bool {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case h.queue <- m:
+			return true
+		case <-t.C:
+			return false
+		}
+	}
+}
+
+// Spill hands the message to fn instead of silently discarding it when the
+// queue is full. The message still counts as dropped in Stats, since fn is
+// typically a side channel such as a disk spool rather than normal
+// processing.
+func Spill(fn func(SyslogMessage)) OverflowPolicy {
+	return func(h *BaseHandler, m SyslogMessage) bool {
+		select {
+		case h.queue <- m:
+			return true
+		default:
+			fn(m)
+			return false
+		}
+	}
+}
+
+// Stats summarizes a BaseHandler's queue activity, suitable for exposing on
+// a status endpoint.
+type Stats struct {
+	Enqueued     uint64
+	Dropped      uint64
+	Processed    uint64
+	QueueLen     int
+	QueueCap     int
+	LastDropTime time.Time
+}
+
+// handlerStats holds the counters backing BaseHandler.Stats. Counters are
+// updated from Handle and Get, which may run on different goroutines.
+type handlerStats struct {
+	enqueued  uint64
+	dropped   uint64
+	processed uint64
+
+	mu       sync.Mutex
+	lastDrop time.Time
+}
+
+func (s *handlerStats) addEnqueued()  { atomic.AddUint64(&s.enqueued, 1) }
+func (s *handlerStats) addProcessed() { atomic.AddUint64(&s.processed, 1) }
+
+func (s *handlerStats) addDropped() {
+	atomic.AddUint64(&s.dropped, 1)
+	s.mu.Lock()
+	s.lastDrop = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *handlerStats) getLastDrop() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastDrop
+}
+
 // BaseHandler is designed to simplify the creation of real handlers. It
 // implements Handler interface using nonblocking queuing of messages and
 // simple message filtering.
 type BaseHandler struct {
-	queue  chan SyslogMessage
-	end    chan struct{}
-	filter func(SyslogMessage) bool
-	ft     bool
+	queue    chan SyslogMessage
+	end      chan struct{}
+	filter   func(SyslogMessage) bool
+	ft       bool
+	overflow OverflowPolicy
+	stats    handlerStats
 }
 
 // NewBaseHandler creates BaseHandler using a specified filter. If filter is nil
 // or if it returns true messages are passed to BaseHandler internal queue
 // (of qlen length). If filter returns false or ft is true messages are returned
-// to server for future processing by other handlers.
+// to server for future processing by other handlers. Messages that arrive
+// while the queue is full are dropped, same as before OverflowPolicy existed;
+// use NewBaseHandlerWithOptions to choose a different policy.
 func NewBaseHandler(qlen int, filter func(SyslogMessage) bool, ft bool) *BaseHandler {
+	return NewBaseHandlerWithOptions(qlen, filter, ft, DropNewest())
+}
+
+// NewBaseHandlerWithOptions is like NewBaseHandler but lets the caller choose
+// the OverflowPolicy applied when the internal queue is full.
+func NewBaseHandlerWithOptions(qlen int, filter func(SyslogMessage) bool, ft bool, overflow OverflowPolicy) *BaseHandler {
 	return &BaseHandler{
-		queue:  make(chan SyslogMessage, qlen),
-		end:    make(chan struct{}),
-		filter: filter,
-		ft:     ft,
+		queue:    make(chan SyslogMessage, qlen),
+		end:      make(chan struct{}),
+		filter:   filter,
+		ft:       ft,
+		overflow: overflow,
 	}
 }
 
 // Handle inserts m in an internal queue. It immediately returns even if
-// queue is full. If m == nil it closes queue and waits for End method call
-// before return.
+// queue is full, applying the handler's OverflowPolicy; the exception is a
+// blocking policy such as BlockWithTimeout, which waits up to its timeout
+// for room. If m == nil it
+// closes queue and waits for End method call before return.
 func (h *BaseHandler) Handle(m SyslogMessage) SyslogMessage {
 	if m == nil {
 		close(h.queue) // signal that there is no more messages for processing
@@ -44,10 +170,10 @@ func (h *BaseHandler) Handle(m SyslogMessage) SyslogMessage {
 		// m doesn't match the filter
 		return m
 	}
-	// Try queue m
-	select {
-	case h.queue <- m:
-	default:
+	if h.overflow(h, m) {
+		h.stats.addEnqueued()
+	} else {
+		h.stats.addDropped()
 	}
 	if h.ft {
 		return m
@@ -61,11 +187,24 @@ func (h *BaseHandler) Handle(m SyslogMessage) SyslogMessage {
 func (h *BaseHandler) Get() SyslogMessage {
 	m, ok := <-h.queue
 	if ok {
+		h.stats.addProcessed()
 		return m
 	}
 	return nil
 }
 
+// Stats returns a snapshot of the handler's queue counters.
+func (h *BaseHandler) Stats() Stats {
+	return Stats{
+		Enqueued:     atomic.LoadUint64(&h.stats.enqueued),
+		Dropped:      atomic.LoadUint64(&h.stats.dropped),
+		Processed:    atomic.LoadUint64(&h.stats.processed),
+		QueueLen:     len(h.queue),
+		QueueCap:     cap(h.queue),
+		LastDropTime: h.stats.getLastDrop(),
+	}
+}
+
 // Queue returns the BaseHandler internal queue as a read-only channel. You can use
 // it directly, especially if your handler needs to select from multiple channels
 // or have to work without blocking. You need to check if this channel is closed by
@@ -78,4 +217,4 @@ func (h *BaseHandler) Queue() <-chan SyslogMessage {
 // only if Get has returned nil before.
 func (h *BaseHandler) End() {
 	close(h.end)
-}
\ No newline at end of file
+}
@@ -0,0 +1,24 @@
+package syslog
+
+import "testing"
+
+func TestDropOldestCountsEvictionsAsDropped(t *testing.T) {
+	h := NewBaseHandlerWithOptions(2, nil, false, DropOldest())
+
+	h.Handle(SyslogMessage{"i": 1})
+	h.Handle(SyslogMessage{"i": 2})
+	h.Handle(SyslogMessage{"i": 3}) // queue is full; evicts {i:1}
+
+	stats := h.Stats()
+	if stats.Dropped != 1 {
+		t.Fatalf("expected 1 dropped message, got %d", stats.Dropped)
+	}
+	if stats.Enqueued != 3 {
+		t.Fatalf("expected 3 enqueued messages, got %d", stats.Enqueued)
+	}
+
+	got := h.Get()
+	if got["i"] != 2 {
+		t.Fatalf("expected the oldest surviving message to be {i:2}, got %v", got)
+	}
+}
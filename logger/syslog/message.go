@@ -0,0 +1,52 @@
+package syslog
+
+// SyslogMessage is a parsed syslog message represented as a set of named
+// fields (e.g. "facility", "severity", "hostname", "app_name",
+// "structured_data"), following the convention used throughout the rest of
+// this package's handlers.
+type SyslogMessage map[string]interface{}
+
+// Facility returns the facility code carried by m, or LOG_USER if m has no
+// facility field.
+func (m SyslogMessage) Facility() Facility {
+	if f, ok := m["facility"].(Facility); ok {
+		return f
+	}
+	return LOG_USER
+}
+
+// Severity returns the severity level carried by m, or LOG_INFO if m has no
+// severity field.
+func (m SyslogMessage) Severity() Severity {
+	if s, ok := m["severity"].(Severity); ok {
+		return s
+	}
+	return LOG_INFO
+}
+
+// Hostname returns the hostname field of m, or the empty string if absent.
+func (m SyslogMessage) Hostname() string {
+	if h, ok := m["hostname"].(string); ok {
+		return h
+	}
+	return ""
+}
+
+// AppName returns the app-name field of m, or the empty string if absent.
+func (m SyslogMessage) AppName() string {
+	if a, ok := m["app_name"].(string); ok {
+		return a
+	}
+	return ""
+}
+
+// HasStructuredDataElement reports whether m carries a structured-data
+// element identified by id (e.g. "exampleSDID@32473").
+func (m SyslogMessage) HasStructuredDataElement(id string) bool {
+	sd, ok := m["structured_data"].(map[string]map[string]string)
+	if !ok {
+		return false
+	}
+	_, ok = sd[id]
+	return ok
+}
@@ -0,0 +1,143 @@
+// Package middleware provides a small standard set of syslog.Middleware
+// implementations for composing cross-cutting concerns onto a
+// syslog.Handler without forking BaseHandler.
+package middleware
+
+import (
+	"hash/fnv"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/karthequian/deis/logger/syslog"
+)
+
+// Recover wraps h so that a panic raised while handling a message is caught
+// and logged via logger instead of crashing the process. The panicking
+// call returns nil, as if the message had been consumed.
+func Recover(logger *log.Logger) syslog.Middleware {
+	return func(h syslog.Handler) syslog.Handler {
+		return syslog.HandlerFunc(func(m syslog.SyslogMessage) (result syslog.SyslogMessage) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Printf("syslog: recovered from panic in handler: %v", r)
+					result = nil
+				}
+			}()
+			return h.Handle(m)
+		})
+	}
+}
+
+// tokenBucket is a simple token-bucket limiter refilled continuously at
+// n tokens per, used by Rate.
+type tokenBucket struct {
+	mu     sync.Mutex
+	tokens float64
+	max    float64
+	refill float64 // tokens per second
+	last   time.Time
+}
+
+func newTokenBucket(n int, per time.Duration) *tokenBucket {
+	return &tokenBucket{
+		tokens: float64(n),
+		max:    float64(n),
+		refill: float64(n) / per.Seconds(),
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += b.refill * now.Sub(b.last).Seconds()
+	if b.tokens > b.max {
+		b.tokens = b.max
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Rate limits a handler to n messages per duration per, dropping messages
+// received once the budget is exhausted until it refills.
+func Rate(n int, per time.Duration) syslog.Middleware {
+	return func(h syslog.Handler) syslog.Handler {
+		limiter := newTokenBucket(n, per)
+		return syslog.HandlerFunc(func(m syslog.SyslogMessage) syslog.SyslogMessage {
+			if m == nil {
+				return h.Handle(nil)
+			}
+			if !limiter.allow() {
+				return nil
+			}
+			return h.Handle(m)
+		})
+	}
+}
+
+// Sample keeps roughly 1/n of messages, chosen deterministically by hashing
+// hostname+app-name so that every message for a given host/app is sampled
+// the same way.
+func Sample(n int) syslog.Middleware {
+	return func(h syslog.Handler) syslog.Handler {
+		return syslog.HandlerFunc(func(m syslog.SyslogMessage) syslog.SyslogMessage {
+			if m == nil {
+				return h.Handle(nil)
+			}
+			if n > 1 {
+				key := m.Hostname() + "\x00" + m.AppName()
+				sum := fnv.New32a()
+				sum.Write([]byte(key))
+				if sum.Sum32()%uint32(n) != 0 {
+					return nil
+				}
+			}
+			return h.Handle(m)
+		})
+	}
+}
+
+// Tag injects kv as a "tags" structured-data element on every message
+// passing through, without mutating the caller's message.
+func Tag(kv map[string]string) syslog.Middleware {
+	return func(h syslog.Handler) syslog.Handler {
+		return syslog.HandlerFunc(func(m syslog.SyslogMessage) syslog.SyslogMessage {
+			if m == nil {
+				return h.Handle(nil)
+			}
+			tagged := make(syslog.SyslogMessage, len(m)+1)
+			for k, v := range m {
+				tagged[k] = v
+			}
+			sd, _ := m["structured_data"].(map[string]map[string]string)
+			merged := make(map[string]map[string]string, len(sd)+1)
+			for id, fields := range sd {
+				merged[id] = fields
+			}
+			merged["tags"] = kv
+			tagged["structured_data"] = merged
+			return h.Handle(tagged)
+		})
+	}
+}
+
+// Timing reports the wall-clock time spent in h.Handle to fn, including
+// calls made to shut h down.
+func Timing(fn func(time.Duration)) syslog.Middleware {
+	return func(h syslog.Handler) syslog.Handler {
+		return syslog.HandlerFunc(func(m syslog.SyslogMessage) syslog.SyslogMessage {
+			start := time.Now()
+			result := h.Handle(m)
+			fn(time.Since(start))
+			return result
+		})
+	}
+}
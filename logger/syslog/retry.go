@@ -0,0 +1,257 @@
+package syslog
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryFunc processes a single message, returning an error if it should be
+// retried.
+type RetryFunc func(SyslogMessage) error
+
+// retryItem is one pending retry, ordered by readyAt in retryHeap.
+type retryItem struct {
+	message SyslogMessage
+	attempt int
+	readyAt time.Time
+}
+
+// retryHeap is a min-heap of retryItems ordered by readyAt.
+type retryHeap []*retryItem
+
+func (h retryHeap) Len() int            { return len(h) }
+func (h retryHeap) Less(i, j int) bool  { return h[i].readyAt.Before(h[j].readyAt) }
+func (h retryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *retryHeap) Push(x interface{}) { *h = append(*h, x.(*retryItem)) }
+func (h *retryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// RetryHandler wraps a RetryFunc with exponential backoff. A message that
+// fails is re-queued with delay base*2^attempt (capped at max, optionally
+// jittered) and retried until maxAttempts is reached, at which point it is
+// forwarded to the deadLetter handler. It implements Handler so it can be composed with
+// BaseHandler, RouterHandler, and the rest of the package.
+//
+// fn is never called concurrently with itself: the intake worker (first
+// attempts) and the retry loop (later attempts) share a lock around the
+// call, so a RetryFunc forwarding to a non-concurrency-safe upstream does
+// not need its own synchronization.
+type RetryHandler struct {
+	fn           RetryFunc
+	callMu       sync.Mutex
+	maxAttempts  int
+	base         time.Duration
+	max          time.Duration
+	jitter       bool
+	deadLetter   Handler
+	drainTimeout time.Duration
+
+	intake     *BaseHandler
+	schedule   chan *retryItem
+	shutdown   chan chan []*retryItem
+	intakeDone chan struct{}
+	loopDone   chan struct{}
+}
+
+// call invokes fn, serialized against concurrent calls from the intake
+// worker and the retry loop.
+func (rh *RetryHandler) call(m SyslogMessage) error {
+	rh.callMu.Lock()
+	defer rh.callMu.Unlock()
+	return rh.fn(m)
+}
+
+// NewRetryHandler creates a RetryHandler that calls fn for each message. A
+// failing message is retried up to maxAttempts times (including the first
+// attempt) with exponential backoff between base and max; if jitter is true
+// each delay is replaced by a random duration between zero and that delay,
+// so it never exceeds max. A message that exhausts its
+// attempts is handed to deadLetter, which may be nil to drop it silently.
+// drainTimeout bounds how long Handle(nil) waits for in-flight retries to
+// finish before flushing whatever remains straight to deadLetter.
+func NewRetryHandler(fn RetryFunc, maxAttempts int, base, max time.Duration, jitter bool, deadLetter Handler, drainTimeout time.Duration) *RetryHandler {
+	rh := &RetryHandler{
+		fn:           fn,
+		maxAttempts:  maxAttempts,
+		base:         base,
+		max:          max,
+		jitter:       jitter,
+		deadLetter:   deadLetter,
+		drainTimeout: drainTimeout,
+		intake:       NewBaseHandler(64, nil, false),
+		schedule:     make(chan *retryItem),
+		shutdown:     make(chan chan []*retryItem),
+		intakeDone:   make(chan struct{}),
+		loopDone:     make(chan struct{}),
+	}
+	go rh.intakeLoop()
+	go rh.retryLoop()
+	return rh
+}
+
+// Handle queues m for processing. If m is nil it stops accepting new
+// messages, waits for pending retries to drain (up to drainTimeout),
+// forwards any still-pending messages to the dead-letter handler, shuts
+// that handler down too, and returns nil.
+func (rh *RetryHandler) Handle(m SyslogMessage) SyslogMessage {
+	if m == nil {
+		rh.intake.Handle(nil)
+		<-rh.intakeDone
+
+		resp := make(chan []*retryItem, 1)
+		rh.shutdown <- resp
+		leftover := <-resp
+		<-rh.loopDone
+
+		for _, item := range leftover {
+			if rh.deadLetter != nil {
+				rh.deadLetter.Handle(item.message)
+			}
+		}
+		if rh.deadLetter != nil {
+			rh.deadLetter.Handle(nil)
+		}
+		return nil
+	}
+	return rh.intake.Handle(m)
+}
+
+func (rh *RetryHandler) intakeLoop() {
+	defer close(rh.intakeDone)
+	for {
+		m := rh.intake.Get()
+		if m == nil {
+			rh.intake.End()
+			return
+		}
+		if err := rh.call(m); err != nil {
+			if rh.maxAttempts <= 1 {
+				if rh.deadLetter != nil {
+					rh.deadLetter.Handle(m)
+				}
+				continue
+			}
+			rh.scheduleRetry(m, 1)
+		}
+	}
+}
+
+// scheduleRetry hands a failed message to the retry loop for the given
+// attempt number, which owns the heap and decides whether to retry again or
+// dead-letter it.
+func (rh *RetryHandler) scheduleRetry(m SyslogMessage, attempt int) {
+	rh.schedule <- &retryItem{
+		message: m,
+		attempt: attempt,
+		readyAt: time.Now().Add(rh.backoff(attempt)),
+	}
+}
+
+// backoff returns the delay before the given attempt, base*2^(attempt-1)
+// capped at max, optionally jittered by up to the computed delay.
+func (rh *RetryHandler) backoff(attempt int) time.Duration {
+	d := rh.base << uint(attempt-1)
+	if d <= 0 || d > rh.max {
+		d = rh.max
+	}
+	if rh.jitter {
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	}
+	return d
+}
+
+// retryLoop owns the retry heap exclusively, so it can pop and re-push
+// items without synchronization. It drains items whose readyAt has passed,
+// retrying them via fn, and otherwise sleeps until the next one is due.
+func (rh *RetryHandler) retryLoop() {
+	defer close(rh.loopDone)
+
+	var h retryHeap
+	heap.Init(&h)
+
+	timer := time.NewTimer(time.Hour)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	armed := false
+
+	rearm := func() {
+		if armed && !timer.Stop() {
+			<-timer.C
+		}
+		armed = false
+		if h.Len() == 0 {
+			return
+		}
+		d := time.Until(h[0].readyAt)
+		if d < 0 {
+			d = 0
+		}
+		timer.Reset(d)
+		armed = true
+	}
+
+	runDue := func() {
+		now := time.Now()
+		for h.Len() > 0 && !h[0].readyAt.After(now) {
+			item := heap.Pop(&h).(*retryItem)
+			if err := rh.call(item.message); err == nil {
+				continue
+			}
+			if item.attempt+1 >= rh.maxAttempts {
+				if rh.deadLetter != nil {
+					rh.deadLetter.Handle(item.message)
+				}
+				continue
+			}
+			item.attempt++
+			item.readyAt = now.Add(rh.backoff(item.attempt))
+			heap.Push(&h, item)
+		}
+	}
+
+	for {
+		select {
+		case item := <-rh.schedule:
+			heap.Push(&h, item)
+			rearm()
+
+		case <-timer.C:
+			armed = false
+			runDue()
+			rearm()
+
+		case resp := <-rh.shutdown:
+			deadline := time.NewTimer(rh.drainTimeout)
+		drain:
+			for h.Len() > 0 {
+				runDue()
+				if h.Len() == 0 {
+					break
+				}
+				rearm()
+				select {
+				case item := <-rh.schedule:
+					heap.Push(&h, item)
+				case <-timer.C:
+					armed = false
+				case <-deadline.C:
+					break drain
+				}
+			}
+			deadline.Stop()
+			remaining := make([]*retryItem, h.Len())
+			copy(remaining, h)
+			h = nil
+			resp <- remaining
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package syslog
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryHandlerMaxAttemptsOneDeadLettersWithoutRetrying(t *testing.T) {
+	var calls int32
+	fn := func(SyslogMessage) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("boom")
+	}
+
+	var mu sync.Mutex
+	var deadLettered []SyslogMessage
+	deadLetter := &fnHandler{handle: func(m SyslogMessage) SyslogMessage {
+		if m != nil {
+			mu.Lock()
+			deadLettered = append(deadLettered, m)
+			mu.Unlock()
+		}
+		return nil
+	}}
+
+	rh := NewRetryHandler(fn, 1, time.Millisecond, time.Millisecond, false, deadLetter, 50*time.Millisecond)
+	rh.Handle(SyslogMessage{"i": 1})
+	rh.Handle(nil)
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected fn to be called exactly once with maxAttempts=1, got %d", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deadLettered) != 1 {
+		t.Fatalf("expected exactly one message to be dead-lettered, got %d", len(deadLettered))
+	}
+}
+
+func TestRetryHandlerRetriesThenSucceeds(t *testing.T) {
+	var calls int32
+	fn := func(SyslogMessage) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	}
+
+	rh := NewRetryHandler(fn, 5, time.Millisecond, time.Millisecond, false, nil, 500*time.Millisecond)
+	rh.Handle(SyslogMessage{"i": 1})
+	rh.Handle(nil)
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected fn to be called 3 times before succeeding, got %d", got)
+	}
+}
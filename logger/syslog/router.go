@@ -0,0 +1,160 @@
+package syslog
+
+import (
+	"path"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// Match describes a set of predicates used to select messages for a
+// RouterHandler rule. A nil or empty field is not checked, so the zero
+// Match matches every message.
+type Match struct {
+	// Facility, when non-nil, restricts matching to messages with this
+	// facility. It is a pointer (see FacilityPtr) rather than a plain
+	// Facility because Facility's zero value, LOG_KERN, is itself a valid
+	// facility and can't double as "unset".
+	Facility *Facility
+
+	// MinSeverity and MaxSeverity, when non-nil, restrict matching to
+	// messages whose severity falls within [MinSeverity, MaxSeverity].
+	MinSeverity *Severity
+	MaxSeverity *Severity
+
+	// HostnameGlob, when non-empty, is matched against the message hostname
+	// using path.Match shell-style globbing.
+	HostnameGlob string
+
+	// AppName, when non-nil, is matched against the message app-name.
+	AppName *regexp.Regexp
+
+	// StructuredDataID, when non-empty, requires the message to carry a
+	// structured-data element with this id.
+	StructuredDataID string
+}
+
+// FacilityPtr returns a pointer to f, for populating Match.Facility from a
+// constant, e.g. syslog.Match{Facility: syslog.FacilityPtr(syslog.LOG_AUTH)}.
+func FacilityPtr(f Facility) *Facility {
+	return &f
+}
+
+// Matches reports whether m satisfies every predicate set on r.
+func (r Match) Matches(m SyslogMessage) bool {
+	if r.Facility != nil && m.Facility() != *r.Facility {
+		return false
+	}
+	if r.MinSeverity != nil && m.Severity() < *r.MinSeverity {
+		return false
+	}
+	if r.MaxSeverity != nil && m.Severity() > *r.MaxSeverity {
+		return false
+	}
+	if r.HostnameGlob != "" {
+		if ok, err := path.Match(r.HostnameGlob, m.Hostname()); err != nil || !ok {
+			return false
+		}
+	}
+	if r.AppName != nil && !r.AppName.MatchString(m.AppName()) {
+		return false
+	}
+	if r.StructuredDataID != "" && !m.HasStructuredDataElement(r.StructuredDataID) {
+		return false
+	}
+	return true
+}
+
+// routerRule pairs a predicate with the Handler it dispatches to.
+type routerRule struct {
+	matches func(SyslogMessage) bool
+	handler Handler
+}
+
+// routerState is the immutable snapshot read by Handle. On and Default
+// build a new routerState rather than mutating one in place, so Handle can
+// read it via a single atomic load with no copying or locking.
+type routerState struct {
+	rules []routerRule
+	def   Handler
+}
+
+// RouterHandler dispatches messages to child Handlers based on matcher
+// predicates registered with On or OnFunc. Rules are tried in registration
+// order; each matched Handler may consume the message (by returning nil,
+// same as BaseHandler) or propagate it to the next rule (by returning it
+// unchanged). A message not consumed by any rule, or carrying no matching
+// rule at all, is passed to the Default handler if one is set.
+type RouterHandler struct {
+	mu    sync.Mutex // serializes writers; Handle never takes this lock
+	state atomic.Value
+}
+
+// NewRouter creates an empty RouterHandler. Rules and the default handler
+// can be added at any time, including concurrently with Handle.
+func NewRouter() *RouterHandler {
+	r := &RouterHandler{}
+	r.state.Store(&routerState{})
+	return r
+}
+
+// On registers h to receive messages matching m, in registration order.
+func (r *RouterHandler) On(m Match, h Handler) {
+	r.OnFunc(m.Matches, h)
+}
+
+// OnFunc registers h to receive messages for which match returns true, in
+// registration order.
+func (r *RouterHandler) OnFunc(match func(SyslogMessage) bool, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.state.Load().(*routerState)
+	rules := make([]routerRule, len(old.rules)+1)
+	copy(rules, old.rules)
+	rules[len(old.rules)] = routerRule{matches: match, handler: h}
+	r.state.Store(&routerState{rules: rules, def: old.def})
+}
+
+// Default sets the handler that receives messages left over after all
+// rules have run. There is at most one default handler; calling Default
+// again replaces it.
+func (r *RouterHandler) Default(h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.state.Load().(*routerState)
+	r.state.Store(&routerState{rules: old.rules, def: h})
+}
+
+// Handle implements Handler. If m is nil it shuts down every registered
+// handler, including the default, in registration order, and waits for
+// each to complete before returning nil.
+func (r *RouterHandler) Handle(m SyslogMessage) SyslogMessage {
+	st := r.state.Load().(*routerState)
+	rules := st.rules
+	def := st.def
+
+	if m == nil {
+		for _, rule := range rules {
+			rule.handler.Handle(nil)
+		}
+		if def != nil {
+			def.Handle(nil)
+		}
+		return nil
+	}
+
+	cur := m
+	for _, rule := range rules {
+		if !rule.matches(cur) {
+			continue
+		}
+		cur = rule.handler.Handle(cur)
+		if cur == nil {
+			return nil
+		}
+	}
+	if def != nil {
+		return def.Handle(cur)
+	}
+	return cur
+}
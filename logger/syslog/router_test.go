@@ -0,0 +1,82 @@
+package syslog
+
+import "testing"
+
+// fnHandler adapts a function to Handler for use across this package's
+// tests, the same way HandlerFunc does for production code.
+type fnHandler struct {
+	handle func(SyslogMessage) SyslogMessage
+}
+
+func (h *fnHandler) Handle(m SyslogMessage) SyslogMessage { return h.handle(m) }
+
+func TestRouterHandlerConsumeStopsPropagation(t *testing.T) {
+	var gotA, gotB bool
+	r := NewRouter()
+	r.OnFunc(func(SyslogMessage) bool { return true }, &fnHandler{handle: func(m SyslogMessage) SyslogMessage {
+		gotA = true
+		return nil // consume
+	}})
+	r.OnFunc(func(SyslogMessage) bool { return true }, &fnHandler{handle: func(m SyslogMessage) SyslogMessage {
+		gotB = true
+		return m
+	}})
+
+	r.Handle(SyslogMessage{"hostname": "h1"})
+
+	if !gotA {
+		t.Fatal("expected first rule to run")
+	}
+	if gotB {
+		t.Fatal("second rule should not run after first rule consumed the message")
+	}
+}
+
+func TestRouterHandlerPropagatesToNextRule(t *testing.T) {
+	var gotB bool
+	r := NewRouter()
+	r.OnFunc(func(SyslogMessage) bool { return true }, &fnHandler{handle: func(m SyslogMessage) SyslogMessage {
+		return m // propagate
+	}})
+	r.OnFunc(func(SyslogMessage) bool { return true }, &fnHandler{handle: func(m SyslogMessage) SyslogMessage {
+		gotB = true
+		return nil
+	}})
+
+	r.Handle(SyslogMessage{})
+
+	if !gotB {
+		t.Fatal("expected message to propagate to the second rule")
+	}
+}
+
+func TestRouterHandlerDefaultReceivesUnmatched(t *testing.T) {
+	var gotDefault bool
+	r := NewRouter()
+	r.OnFunc(func(SyslogMessage) bool { return false }, &fnHandler{handle: func(m SyslogMessage) SyslogMessage { return nil }})
+	r.Default(&fnHandler{handle: func(m SyslogMessage) SyslogMessage {
+		gotDefault = true
+		return nil
+	}})
+
+	r.Handle(SyslogMessage{})
+
+	if !gotDefault {
+		t.Fatal("expected unmatched message to reach the default handler")
+	}
+}
+
+func TestMatchFacilityPointerDisambiguatesZeroValue(t *testing.T) {
+	kern := FacilityPtr(LOG_KERN)
+	m := Match{Facility: kern}
+
+	if !m.Matches(SyslogMessage{"facility": LOG_KERN}) {
+		t.Fatal("expected a LOG_KERN match to match a LOG_KERN message")
+	}
+	if m.Matches(SyslogMessage{"facility": LOG_USER}) {
+		t.Fatal("expected a LOG_KERN match to not match a LOG_USER message")
+	}
+	if !(Match{}).Matches(SyslogMessage{"facility": LOG_USER}) {
+		t.Fatal("expected the zero Match to match any facility, including non-LOG_KERN ones")
+	}
+}
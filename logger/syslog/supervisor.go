@@ -0,0 +1,83 @@
+package syslog
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Supervisor owns a set of named Handlers and shuts them down gracefully on
+// SIGINT, SIGTERM, or SIGQUIT: each registered Handler receives Handle(nil)
+// in registration order, and Supervisor waits up to a per-handler deadline
+// for it to return before moving on to the next one. This removes the need
+// for every binary wiring a Handler pipeline to duplicate that shutdown
+// boilerplate.
+type Supervisor struct {
+	mu       sync.Mutex
+	names    []string
+	handlers []Handler
+
+	deadline time.Duration
+	sig      chan os.Signal
+	done     chan struct{}
+}
+
+// NewSupervisor creates a Supervisor that gives each registered handler up
+// to deadline to shut down once SIGINT, SIGTERM, or SIGQUIT is received.
+func NewSupervisor(deadline time.Duration) *Supervisor {
+	s := &Supervisor{
+		deadline: deadline,
+		sig:      make(chan os.Signal, 1),
+		done:     make(chan struct{}),
+	}
+	signal.Notify(s.sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	go s.run()
+	return s
+}
+
+// Register adds h, identified by name for logging, to the set shut down
+// when a signal arrives. Handlers are shut down in registration order.
+func (s *Supervisor) Register(name string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.names = append(s.names, name)
+	s.handlers = append(s.handlers, h)
+}
+
+// Done returns a channel that is closed once every registered handler has
+// been given a chance to shut down. A caller's main function typically
+// blocks on <-sup.Done().
+func (s *Supervisor) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Supervisor) run() {
+	sig := <-s.sig
+	signal.Stop(s.sig)
+	log.Printf("syslog: received %s, shutting down handlers", sig)
+
+	s.mu.Lock()
+	names := append([]string(nil), s.names...)
+	handlers := append([]Handler(nil), s.handlers...)
+	s.mu.Unlock()
+
+	for i, h := range handlers {
+		name := names[i]
+		ended := make(chan struct{})
+		go func(h Handler) {
+			h.Handle(nil)
+			close(ended)
+		}(h)
+
+		select {
+		case <-ended:
+			log.Printf("syslog: handler %q shut down cleanly", name)
+		case <-time.After(s.deadline):
+			log.Printf("syslog: handler %q did not shut down within %s, continuing", name, s.deadline)
+		}
+	}
+	close(s.done)
+}